@@ -37,6 +37,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"math"
 	"strconv"
 	"unicode/utf8"
@@ -63,8 +64,17 @@ const (
 
 // Builder is a low-level JSON builder with a caller-driven API.
 // It can generatet both compact JSON as well as pretty-printed output with almost zero overhead.
+//
+// Builder buffers its output internally, in its embedded bytes.Buffer, and
+// writes through to an io.Writer sink at value boundaries (i.e. once
+// nesting returns to depth zero), so a long-lived Builder streaming many
+// top-level values never holds more than one value in memory at a time.
+// The zero value has no sink: it behaves exactly like the original
+// bytes.Buffer-based Builder, accumulating everything in memory for
+// Bytes()/String() to return.
 type Builder struct {
-	bytes.Buffer // output JSON
+	bytes.Buffer           // staging buffer; flushed to w at value boundaries
+	w            io.Writer // output sink; nil means "keep everything in the Buffer"
 
 	// Err holds the first error encountered, if any
 	Err error
@@ -73,18 +83,62 @@ type Builder struct {
 	Indent  string
 	KeyTerm []byte // key terminator. Defaults to ":"
 
-	// w         bytes.Buffer     // output JSON
 	state     builderState // most recently built thing
 	scratch   []byte       // temporary storage for intermediate encoding
 	nestdepth int
 }
 
+// NewBuilder returns a Builder that flushes completed top-level values to w
+// as it builds them, instead of accumulating the whole document in memory.
+func NewBuilder(w io.Writer) *Builder {
+	return &Builder{w: w}
+}
+
+// SetOutput changes the Builder's output sink. Pass nil to go back to
+// accumulating the whole document in memory for Bytes()/String().
+func (e *Builder) SetOutput(w io.Writer) {
+	e.w = w
+}
+
 func (e *Builder) setError(err error) {
 	if e.Err == nil {
 		e.Err = err
 	}
 }
 
+// Write implements io.Writer, appending p to the Builder's staging buffer.
+func (e *Builder) Write(p []byte) (int, error) {
+	return e.Buffer.Write(p)
+}
+
+// WriteByte appends b to the Builder's staging buffer.
+func (e *Builder) WriteByte(b byte) error {
+	return e.Buffer.WriteByte(b)
+}
+
+// Bytes returns the bytes written so far that have not yet been flushed to
+// an output sink. For a Builder with no sink (the zero value, or after
+// SetOutput(nil)), this is the entire document built so far.
+func (e *Builder) Bytes() []byte { return e.Buffer.Bytes() }
+
+// String is the string form of Bytes.
+func (e *Builder) String() string { return e.Buffer.String() }
+
+// flush writes any buffered bytes to the output sink once building has
+// returned to depth zero, i.e. a complete top-level value (or, for
+// LineBuilder, a complete line) has just finished. With no sink set, flush
+// is a no-op and bytes simply accumulate in the Buffer, matching the original
+// in-memory Builder behavior.
+func (e *Builder) flush() {
+	if e.w == nil || e.nestdepth != 0 || e.Buffer.Len() == 0 {
+		return
+	}
+	if _, err := e.w.Write(e.Buffer.Bytes()); err != nil {
+		e.setError(err)
+	}
+	e.Buffer.Reset()
+}
+
 func (e *Builder) startChunk(nextstate builderState) {
 	switch e.state {
 
@@ -122,9 +176,8 @@ func (e *Builder) writeNewLine() {
 	}
 }
 
-// Reset resets the Builder so it can be reused. Does not reset Indent.
-// If the ByteWriter has a Reset() method, that method is called as well, which is the case
-// when the default bytes.Buffer is being used.
+// Reset resets the Builder so it can be reused. Does not reset Indent or
+// the output sink set via NewBuilder/SetOutput.
 func (e *Builder) Reset() {
 	e.Buffer.Reset()
 	e.Err = nil
@@ -184,6 +237,7 @@ func (e *Builder) End(kind byte) {
 	}
 	e.WriteByte(kind)
 	e.state = builderValue
+	e.flush()
 }
 
 // InObject returns true if EndObject can be safely called
@@ -200,6 +254,7 @@ var (
 func (e *Builder) Raw(b []byte) {
 	e.startChunk(builderValue)
 	e.Write(b)
+	e.flush()
 }
 
 func (e *Builder) Null() { e.Raw(jsonNull) }
@@ -227,16 +282,19 @@ func (e *Builder) Blob(data []byte) {
 	}
 	b64enc.Encode(buf, data)
 	e.WriteJsonString(buf)
+	e.flush()
 }
 
 func (e *Builder) Str(s string) {
 	e.startChunk(builderValue)
 	e.WriteJsonString([]byte(s))
+	e.flush()
 }
 
 func (e *Builder) StrBytes(s []byte) {
 	e.startChunk(builderValue)
 	e.WriteJsonString(s)
+	e.flush()
 }
 
 func (e *Builder) Int(v int64, bitsize int) {
@@ -246,6 +304,7 @@ func (e *Builder) Int(v int64, bitsize int) {
 	} else {
 		fmt.Fprintf(e, "%d", v)
 	}
+	e.flush()
 }
 
 func (e *Builder) Uint(v uint64, bitsize int) {
@@ -255,6 +314,7 @@ func (e *Builder) Uint(v uint64, bitsize int) {
 	} else {
 		fmt.Fprintf(e, "%d", v)
 	}
+	e.flush()
 }
 
 // Float writes a float64 number of bits size
@@ -295,6 +355,7 @@ func (e *Builder) Float(f float64, bits int) {
 
 	e.scratch = b
 	e.Write(b)
+	e.flush()
 }
 
 func (e *Builder) Any(v interface{}) {
@@ -347,6 +408,7 @@ func (e *Builder) Any(v interface{}) {
 			enc.SetIndent("", e.Indent)
 			e.setError(enc.Encode(v))
 			// Note: I can't figure out how to make json.Encoder not to write a trailing linebreak.
+			e.flush()
 		}
 	}
 }
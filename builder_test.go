@@ -1,11 +1,26 @@
 package json
 
 import (
-	// "testing"
 	"fmt"
+	"testing"
 	"time"
 )
 
+// TestBuilderEmbedsBuffer checks that Builder still promotes the full
+// bytes.Buffer method set (Len, Grow, WriteString, ...), since callers of
+// the original embedded-bytes.Buffer Builder relied on it.
+func TestBuilderEmbedsBuffer(t *testing.T) {
+	var b Builder
+	b.Grow(16)
+	b.WriteString("abc")
+	if got := b.Len(); got != 3 {
+		t.Fatalf("Len() = %d, want 3", got)
+	}
+	if got := b.String(); got != "abc" {
+		t.Fatalf("String() = %q, want %q", got, "abc")
+	}
+}
+
 type ExampleVec3 struct {
 	x, y, z float64
 }
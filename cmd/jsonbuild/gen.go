@@ -0,0 +1,246 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"strings"
+)
+
+// generateFile reads the Go source file at inPath, generates BuildJSON and
+// ReadJSON methods (and, where requested, MarshalJSON/UnmarshalJSON) for
+// every json:build-marked struct it finds, and writes the result to
+// outPath.
+func generateFile(inPath, outPath string) error {
+	pkgName, structs, err := scanFile(inPath)
+	if err != nil {
+		return err
+	}
+	if len(structs) == 0 {
+		return fmt.Errorf("no json:build-marked structs found")
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by jsonbuild from %s. DO NOT EDIT.\n\n", filepathBase(inPath))
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+
+	needsStrconv := false
+	needsMarshal := false
+	for _, s := range structs {
+		for _, f := range s.fields {
+			if f.tag.asString {
+				needsStrconv = true
+			}
+		}
+		if s.marshal {
+			needsMarshal = true
+		}
+	}
+
+	buf.WriteString("import (\n")
+	fmt.Fprintf(&buf, "\tjson %q\n", "github.com/rsms/go-json")
+	if needsMarshal {
+		fmt.Fprintf(&buf, "\t%q\n", "github.com/rsms/go-json/jsonbuild")
+	}
+	if needsStrconv {
+		buf.WriteString("\t\"strconv\"\n")
+	}
+	buf.WriteString(")\n\n")
+
+	for _, s := range structs {
+		writeBuildJSON(&buf, s)
+		writeReadJSON(&buf, s)
+		if s.marshal {
+			writeMarshalJSON(&buf, s)
+			writeUnmarshalJSON(&buf, s)
+		}
+	}
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		// Write the unformatted source too, so the failure is debuggable.
+		os.WriteFile(outPath, buf.Bytes(), 0644)
+		return fmt.Errorf("generated invalid Go source: %v", err)
+	}
+	return os.WriteFile(outPath, out, 0644)
+}
+
+func defaultOutputPath(inPath string) string {
+	if strings.HasSuffix(inPath, ".go") {
+		return strings.TrimSuffix(inPath, ".go") + "_jsonbuild.go"
+	}
+	return inPath + "_jsonbuild.go"
+}
+
+func filepathBase(p string) string {
+	if i := strings.LastIndexByte(p, '/'); i >= 0 {
+		return p[i+1:]
+	}
+	return p
+}
+
+func writeBuildJSON(buf *bytes.Buffer, s markedStruct) {
+	fmt.Fprintf(buf, "func (t *%s) BuildJSON(b *json.Builder) {\n", s.name)
+	buf.WriteString("\tb.StartObject()\n")
+	for _, f := range s.fields {
+		writeBuildField(buf, f)
+	}
+	buf.WriteString("\tb.EndObject()\n")
+	buf.WriteString("}\n\n")
+}
+
+func writeBuildField(buf *bytes.Buffer, f structField) {
+	rt, err := resolveType(f.goType)
+	if err != nil {
+		fmt.Fprintf(buf, "\t// SKIPPED %s: %v\n", f.goName, err)
+		return
+	}
+	accessor := "t." + f.goName
+
+	if f.tag.omitempty {
+		fmt.Fprintf(buf, "\tif %s {\n", zeroCheck(rt, accessor))
+		writeValueProp(buf, f.tag.name, accessor, rt, f.tag.asString, "\t\t")
+		buf.WriteString("\t}\n")
+		return
+	}
+	writeValueProp(buf, f.tag.name, accessor, rt, f.tag.asString, "\t")
+}
+
+// zeroCheck returns a Go boolean expression that is true when expr holds a
+// non-zero ("present") value, suitable for guarding an omitempty field.
+func zeroCheck(rt *resolvedType, expr string) string {
+	switch rt.kind {
+	case kindBool:
+		return expr
+	case kindString:
+		return expr + ` != ""`
+	case kindInt, kindUint, kindFloat:
+		return expr + " != 0"
+	case kindBytes, kindSlice:
+		return "len(" + expr + ") > 0"
+	case kindStruct:
+		return "true" // no cheap zero check available; always emit
+	}
+	return "true"
+}
+
+func writeValueProp(buf *bytes.Buffer, key, accessor string, rt *resolvedType, asString bool, indent string) {
+	fmt.Fprintf(buf, "%sb.Key(%q)\n", indent, key)
+	writeValue(buf, accessor, rt, asString, indent)
+}
+
+func writeValue(buf *bytes.Buffer, accessor string, rt *resolvedType, asString bool, indent string) {
+	switch rt.kind {
+	case kindBool:
+		if asString {
+			fmt.Fprintf(buf, "%sif %s {\n%s\tb.Str(\"true\")\n%s} else {\n%s\tb.Str(\"false\")\n%s}\n",
+				indent, accessor, indent, indent, indent, indent)
+		} else {
+			fmt.Fprintf(buf, "%sb.Bool(%s)\n", indent, accessor)
+		}
+	case kindString:
+		fmt.Fprintf(buf, "%sb.Str(%s)\n", indent, accessor)
+	case kindInt:
+		if asString && rt.bits <= 32 {
+			fmt.Fprintf(buf, "%sb.Str(strconv.FormatInt(int64(%s), 10))\n", indent, accessor)
+		} else {
+			fmt.Fprintf(buf, "%sb.Int(int64(%s), %d)\n", indent, accessor, rt.bits)
+		}
+	case kindUint:
+		if asString && rt.bits <= 32 {
+			fmt.Fprintf(buf, "%sb.Str(strconv.FormatUint(uint64(%s), 10))\n", indent, accessor)
+		} else {
+			fmt.Fprintf(buf, "%sb.Uint(uint64(%s), %d)\n", indent, accessor, rt.bits)
+		}
+	case kindFloat:
+		if asString {
+			fmt.Fprintf(buf, "%sb.Str(strconv.FormatFloat(float64(%s), 'g', -1, %d))\n", indent, accessor, rt.bits)
+		} else {
+			fmt.Fprintf(buf, "%sb.Float(float64(%s), %d)\n", indent, accessor, rt.bits)
+		}
+	case kindBytes:
+		fmt.Fprintf(buf, "%sb.Blob(%s)\n", indent, accessor)
+	case kindStruct:
+		fmt.Fprintf(buf, "%s%s.BuildJSON(b)\n", indent, accessor)
+	case kindSlice:
+		fmt.Fprintf(buf, "%sb.StartArray()\n", indent)
+		fmt.Fprintf(buf, "%sfor _, v := range %s {\n", indent, accessor)
+		writeValue(buf, "v", rt.elem, false, indent+"\t")
+		buf.WriteString(indent + "}\n")
+		fmt.Fprintf(buf, "%sb.EndArray()\n", indent)
+	}
+}
+
+func writeReadJSON(buf *bytes.Buffer, s markedStruct) {
+	fmt.Fprintf(buf, "func (t *%s) ReadJSON(r *json.Reader) {\n", s.name)
+	buf.WriteString("\tif !r.ObjectStart() {\n\t\treturn\n\t}\n")
+	buf.WriteString("\tfor r.More() {\n")
+	buf.WriteString("\t\tswitch r.Key() {\n")
+	for _, f := range s.fields {
+		rt, err := resolveType(f.goType)
+		if err != nil {
+			continue // already reported as a BuildJSON skip comment
+		}
+		fmt.Fprintf(buf, "\t\tcase %q:\n", f.tag.name)
+		writeReadField(buf, "t."+f.goName, rt, f.tag.asString, "\t\t\t")
+	}
+	buf.WriteString("\t\tdefault:\n\t\t\tr.Discard()\n")
+	buf.WriteString("\t\t}\n\t}\n}\n\n")
+}
+
+func writeReadField(buf *bytes.Buffer, accessor string, rt *resolvedType, asString bool, indent string) {
+	switch rt.kind {
+	case kindBool:
+		if asString {
+			fmt.Fprintf(buf, "%s%s, _ = strconv.ParseBool(r.Str())\n", indent, accessor)
+		} else {
+			fmt.Fprintf(buf, "%s%s = r.Bool()\n", indent, accessor)
+		}
+	case kindString:
+		fmt.Fprintf(buf, "%s%s = r.Str()\n", indent, accessor)
+	case kindInt:
+		fmt.Fprintf(buf, "%s%s = %s(r.Int(%d))\n", indent, accessor, rt.goName, rt.bits)
+	case kindUint:
+		fmt.Fprintf(buf, "%s%s = %s(r.Uint(%d))\n", indent, accessor, rt.goName, rt.bits)
+	case kindFloat:
+		if asString {
+			fmt.Fprintf(buf, "%sif f, err := strconv.ParseFloat(r.Str(), %d); err == nil {\n", indent, rt.bits)
+			fmt.Fprintf(buf, "%s\t%s = %s(f)\n", indent, accessor, rt.goName)
+			fmt.Fprintf(buf, "%s}\n", indent)
+		} else {
+			fmt.Fprintf(buf, "%s%s = %s(r.Float(%d))\n", indent, accessor, rt.goName, rt.bits)
+		}
+	case kindBytes:
+		fmt.Fprintf(buf, "%s%s = r.Blob()\n", indent, accessor)
+	case kindStruct:
+		fmt.Fprintf(buf, "%s%s.ReadJSON(r)\n", indent, accessor)
+	case kindSlice:
+		fmt.Fprintf(buf, "%sif r.ArrayStart() {\n", indent)
+		fmt.Fprintf(buf, "%s\tfor r.More() {\n", indent)
+		fmt.Fprintf(buf, "%s\t\tvar v %s\n", indent, rt.elem.goName)
+		writeReadField(buf, "v", rt.elem, false, indent+"\t\t")
+		fmt.Fprintf(buf, "%s\t\t%s = append(%s, v)\n", indent, accessor, accessor)
+		fmt.Fprintf(buf, "%s\t}\n", indent)
+		fmt.Fprintf(buf, "%s}\n", indent)
+	}
+}
+
+func writeMarshalJSON(buf *bytes.Buffer, s markedStruct) {
+	fmt.Fprintf(buf, "func (t *%s) MarshalJSON() ([]byte, error) {\n", s.name)
+	buf.WriteString("\tb := jsonbuild.GetBuilder()\n")
+	buf.WriteString("\tdefer jsonbuild.PutBuilder(b)\n")
+	buf.WriteString("\tt.BuildJSON(b)\n")
+	buf.WriteString("\tif b.Err != nil {\n\t\treturn nil, b.Err\n\t}\n")
+	buf.WriteString("\treturn append([]byte(nil), b.Bytes()...), nil\n")
+	buf.WriteString("}\n\n")
+}
+
+func writeUnmarshalJSON(buf *bytes.Buffer, s markedStruct) {
+	fmt.Fprintf(buf, "func (t *%s) UnmarshalJSON(data []byte) error {\n", s.name)
+	buf.WriteString("\tr := jsonbuild.GetReader(data)\n")
+	buf.WriteString("\tdefer jsonbuild.PutReader(r)\n")
+	buf.WriteString("\tt.ReadJSON(r)\n")
+	buf.WriteString("\treturn r.Err()\n")
+	buf.WriteString("}\n\n")
+}
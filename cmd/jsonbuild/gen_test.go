@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateFile(t *testing.T) {
+	in := filepath.Join("testdata", "fixture.go")
+	golden := filepath.Join("testdata", "fixture_jsonbuild.go.golden")
+	out := filepath.Join(t.TempDir(), "fixture_jsonbuild.go")
+
+	if err := generateFile(in, out); err != nil {
+		t.Fatalf("generateFile: %v", err)
+	}
+
+	got, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("reading generated file: %v", err)
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatalf("reading golden file: %v", err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated output does not match golden file\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// TestAsStringRoundTrip checks that writeValue and writeReadField agree on
+// the on-wire representation for json:",string" fields: bool and float
+// values must be written as quoted strings (matching encoding/json's
+// ,string behavior) and read back out of a string token, not the bare
+// numeric/boolean token writeValue/writeReadField use by default.
+func TestAsStringRoundTrip(t *testing.T) {
+	for _, rt := range []*resolvedType{
+		{kind: kindBool, goName: "bool"},
+		{kind: kindFloat, goName: "float64", bits: 64},
+	} {
+		var write, read bytes.Buffer
+		writeValue(&write, "t.V", rt, true, "")
+		writeReadField(&read, "t.V", rt, true, "")
+		if !strings.Contains(write.String(), "b.Str(") {
+			t.Errorf("%s: writeValue with asString=true does not write a string: %s", rt.goName, write.String())
+		}
+		if !strings.Contains(read.String(), "r.Str()") {
+			t.Errorf("%s: writeReadField with asString=true does not read a string: %s", rt.goName, read.String())
+		}
+	}
+}
+
+func TestParseFieldTag(t *testing.T) {
+	cases := []struct {
+		goName, raw string
+		want        fieldTag
+	}{
+		{"Name", "", fieldTag{name: "Name"}},
+		{"Name", "-", fieldTag{name: "Name", omit: true}},
+		{"Name", "name", fieldTag{name: "name"}},
+		{"Name", "name,omitempty", fieldTag{name: "name", omitempty: true}},
+		{"Age", ",string", fieldTag{name: "Age", asString: true}},
+		{"Age", "age,omitempty,string", fieldTag{name: "age", omitempty: true, asString: true}},
+	}
+	for _, c := range cases {
+		got := parseFieldTag(c.goName, c.raw)
+		if got != c.want {
+			t.Errorf("parseFieldTag(%q, %q) = %+v, want %+v", c.goName, c.raw, got, c.want)
+		}
+	}
+}
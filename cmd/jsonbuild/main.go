@@ -0,0 +1,69 @@
+// Command jsonbuild generates BuildJSON and ReadJSON methods for structs
+// marked with a "json:build" comment, so that encoding them no longer has
+// to go through the reflection-based fallback in Builder.Any or a round
+// trip through encoding/json.
+//
+// Usage:
+//
+//	jsonbuild [-out file] file.go
+//
+// Typically it's invoked via a go:generate directive placed in the file
+// that defines the annotated types:
+//
+//	//go:generate jsonbuild
+//
+// which causes `go generate` to run jsonbuild with no arguments, in which
+// case it reads the file to process from the GOFILE environment variable
+// that go generate sets.
+//
+// A struct opts in by having a "json:build" line in its doc comment:
+//
+//	// json:build
+//	type Point struct {
+//		X float64 `json:"x"`
+//		Y float64 `json:"y"`
+//	}
+//
+// Adding "marshal" after the marker also generates MarshalJSON and
+// UnmarshalJSON methods, implemented on top of BuildJSON/ReadJSON using a
+// pooled Builder/Reader from the jsonbuild runtime package:
+//
+//	// json:build marshal
+//	type User struct { ... }
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	outFlag := flag.String("out", "", "output file (default: <input>_jsonbuild.go)")
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 {
+		gofile := os.Getenv("GOFILE")
+		if gofile == "" {
+			fmt.Fprintln(os.Stderr, "jsonbuild: no input file (pass a file argument or run via go:generate)")
+			os.Exit(1)
+		}
+		files = []string{gofile}
+	}
+	if len(files) > 1 && *outFlag != "" {
+		fmt.Fprintln(os.Stderr, "jsonbuild: -out can only be used with a single input file")
+		os.Exit(1)
+	}
+
+	for _, file := range files {
+		out := *outFlag
+		if out == "" {
+			out = defaultOutputPath(file)
+		}
+		if err := generateFile(file, out); err != nil {
+			fmt.Fprintf(os.Stderr, "jsonbuild: %s: %v\n", file, err)
+			os.Exit(1)
+		}
+	}
+}
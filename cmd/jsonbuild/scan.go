@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"reflect"
+	"strings"
+)
+
+const markerPrefix = "json:build"
+
+// markedStruct is a struct type that opted into code generation via a
+// "json:build" doc comment.
+type markedStruct struct {
+	name    string
+	fields  []structField
+	marshal bool // also emit MarshalJSON/UnmarshalJSON
+}
+
+type structField struct {
+	goName string
+	goType ast.Expr
+	tag    fieldTag
+}
+
+// parseMarker reports whether doc contains a "json:build" marker line and,
+// if so, whether it requests the "marshal" option.
+func parseMarker(doc *ast.CommentGroup) (marked, marshal bool) {
+	if doc == nil {
+		return false, false
+	}
+	for _, c := range doc.List {
+		text := strings.TrimSpace(strings.TrimPrefix(c.Text, "//"))
+		if text == markerPrefix {
+			return true, false
+		}
+		if rest := strings.TrimPrefix(text, markerPrefix+" "); rest != text {
+			for _, opt := range strings.Fields(rest) {
+				if opt == "marshal" {
+					marshal = true
+				}
+			}
+			return true, marshal
+		}
+	}
+	return false, false
+}
+
+// scanFile parses the Go source file at path and returns the package name
+// plus every struct type marked for code generation.
+func scanFile(path string) (pkgName string, structs []markedStruct, err error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return "", nil, err
+	}
+	pkgName = f.Name.Name
+
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+			doc := ts.Doc
+			if doc == nil {
+				// A single-spec GenDecl, e.g. `// json:build\ntype Foo struct {...}`,
+				// attaches its doc comment to the GenDecl rather than the TypeSpec.
+				doc = gd.Doc
+			}
+			marked, marshal := parseMarker(doc)
+			if !marked {
+				continue
+			}
+			fields, err := structFields(st)
+			if err != nil {
+				return "", nil, fmt.Errorf("%s: %v", ts.Name.Name, err)
+			}
+			structs = append(structs, markedStruct{
+				name:    ts.Name.Name,
+				fields:  fields,
+				marshal: marshal,
+			})
+		}
+	}
+	return pkgName, structs, nil
+}
+
+func structFields(st *ast.StructType) ([]structField, error) {
+	var fields []structField
+	for _, f := range st.Fields.List {
+		if len(f.Names) == 0 {
+			// Skip embedded fields; not supported in this version.
+			continue
+		}
+		var tagValue string
+		if f.Tag != nil {
+			unquoted, err := unquoteTag(f.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tagValue = reflect.StructTag(unquoted).Get("json")
+		}
+		for _, name := range f.Names {
+			if !name.IsExported() {
+				continue
+			}
+			ft := parseFieldTag(name.Name, tagValue)
+			if ft.omit {
+				continue
+			}
+			fields = append(fields, structField{
+				goName: name.Name,
+				goType: f.Type,
+				tag:    ft,
+			})
+		}
+	}
+	return fields, nil
+}
+
+func unquoteTag(raw string) (string, error) {
+	// raw is the literal token text, e.g. "`json:\"x\"`"
+	if len(raw) >= 2 && raw[0] == '`' {
+		return raw[1 : len(raw)-1], nil
+	}
+	return "", fmt.Errorf("unsupported tag literal: %s", raw)
+}
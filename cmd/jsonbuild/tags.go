@@ -0,0 +1,41 @@
+package main
+
+import "strings"
+
+// fieldTag is the parsed form of a `json:"..."` struct tag, following the
+// same rules as encoding/json: the first comma-separated element is the
+// field name (or empty to keep the Go field name), followed by options
+// such as "omitempty" and "string".
+type fieldTag struct {
+	name      string
+	omit      bool // "-": field is not part of the JSON representation
+	omitempty bool
+	asString  bool // "string": encode/decode the value quoted as a JSON string
+}
+
+// parseFieldTag parses the value of a `json:"..."` struct tag, where raw is
+// the tag text with the json: prefix already stripped (as returned by
+// reflect.StructTag.Get("json")).
+func parseFieldTag(goName, raw string) fieldTag {
+	t := fieldTag{name: goName}
+	if raw == "" {
+		return t
+	}
+	parts := strings.Split(raw, ",")
+	if parts[0] == "-" && len(parts) == 1 {
+		t.omit = true
+		return t
+	}
+	if parts[0] != "" {
+		t.name = parts[0]
+	}
+	for _, opt := range parts[1:] {
+		switch opt {
+		case "omitempty":
+			t.omitempty = true
+		case "string":
+			t.asString = true
+		}
+	}
+	return t
+}
@@ -0,0 +1,26 @@
+package fixture
+
+//go:generate jsonbuild
+
+// Point is a 2D coordinate.
+//
+// json:build
+type Point struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// User is an account record.
+//
+// json:build marshal
+type User struct {
+	ID       int64    `json:"id"`
+	Name     string   `json:"name"`
+	Email    string   `json:"email,omitempty"`
+	Age      int32    `json:"age,string"`
+	Verified bool     `json:"verified,string"`
+	Score    float64  `json:"score,string"`
+	Password string   `json:"-"`
+	Tags     []string `json:"tags,omitempty"`
+	Home     Point    `json:"home"`
+}
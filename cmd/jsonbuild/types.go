@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+)
+
+// fieldKind categorizes a Go field type into the shape of Builder/Reader
+// call it should generate.
+type fieldKind int
+
+const (
+	kindBool fieldKind = iota
+	kindString
+	kindInt
+	kindUint
+	kindFloat
+	kindBytes  // []byte, encoded as a base64 blob
+	kindStruct // named type implementing BuildJSON/ReadJSON
+	kindSlice  // []T, T being one of the above (except kindSlice itself)
+)
+
+// resolvedType describes what a struct field's Go type means for codegen.
+type resolvedType struct {
+	kind   fieldKind
+	bits   int           // bit size, for kindInt/kindUint/kindFloat
+	goName string        // the exact Go type name, e.g. "int32", "Point"
+	elem   *resolvedType // element type, for kindSlice
+}
+
+// resolveType maps a Go AST type expression to a resolvedType, or returns
+// an error if the type isn't supported by this version of jsonbuild.
+func resolveType(expr ast.Expr) (*resolvedType, error) {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "bool":
+			return &resolvedType{kind: kindBool, goName: "bool"}, nil
+		case "string":
+			return &resolvedType{kind: kindString, goName: "string"}, nil
+		case "int":
+			return &resolvedType{kind: kindInt, bits: intSize, goName: "int"}, nil
+		case "int8":
+			return &resolvedType{kind: kindInt, bits: 8, goName: "int8"}, nil
+		case "int16":
+			return &resolvedType{kind: kindInt, bits: 16, goName: "int16"}, nil
+		case "int32", "rune":
+			return &resolvedType{kind: kindInt, bits: 32, goName: t.Name}, nil
+		case "int64":
+			return &resolvedType{kind: kindInt, bits: 64, goName: "int64"}, nil
+		case "uint":
+			return &resolvedType{kind: kindUint, bits: intSize, goName: "uint"}, nil
+		case "uint8", "byte":
+			return &resolvedType{kind: kindUint, bits: 8, goName: t.Name}, nil
+		case "uint16":
+			return &resolvedType{kind: kindUint, bits: 16, goName: "uint16"}, nil
+		case "uint32":
+			return &resolvedType{kind: kindUint, bits: 32, goName: "uint32"}, nil
+		case "uint64":
+			return &resolvedType{kind: kindUint, bits: 64, goName: "uint64"}, nil
+		case "float32":
+			return &resolvedType{kind: kindFloat, bits: 32, goName: "float32"}, nil
+		case "float64":
+			return &resolvedType{kind: kindFloat, bits: 64, goName: "float64"}, nil
+		default:
+			// Assume it's a named type in the same package that has (or will
+			// have) BuildJSON/ReadJSON methods, e.g. another json:build struct.
+			return &resolvedType{kind: kindStruct, goName: t.Name}, nil
+		}
+	case *ast.ArrayType:
+		if t.Len != nil {
+			return nil, fmt.Errorf("fixed-size arrays are not supported")
+		}
+		if elemIdent, ok := t.Elt.(*ast.Ident); ok && (elemIdent.Name == "byte" || elemIdent.Name == "uint8") {
+			return &resolvedType{kind: kindBytes, goName: "[]byte"}, nil
+		}
+		elem, err := resolveType(t.Elt)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported slice element type: %v", err)
+		}
+		if elem.kind == kindSlice {
+			return nil, fmt.Errorf("nested slices are not supported")
+		}
+		return &resolvedType{kind: kindSlice, elem: elem}, nil
+	case *ast.SelectorExpr:
+		// e.g. time.Time: treated like a named struct type, assumed to
+		// implement BuildJSON/ReadJSON.
+		return &resolvedType{kind: kindStruct, goName: t.Sel.Name}, nil
+	default:
+		return nil, fmt.Errorf("unsupported field type %T", expr)
+	}
+}
+
+// intSize is the bit size jsonbuild assumes for a plain "int"/"uint" field,
+// taken from strconv.IntSize: the width of int/uint on the platform jsonbuild
+// itself is running on, not on the target the generated code is built for.
+// Builder.Int/Uint write every value of a bitsize over 32 as a quoted string,
+// so code generated on a 64-bit machine always quotes int/uint fields, and
+// reads them back with Reader.Int/Uint(64) followed by a conversion to
+// int/uint, which on a 32-bit target truncates values outside the 32-bit
+// range without reporting an error.
+const intSize = strconv.IntSize
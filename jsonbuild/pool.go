@@ -0,0 +1,39 @@
+// Package jsonbuild provides small runtime helpers used by code generated
+// by cmd/jsonbuild. Generated MarshalJSON/UnmarshalJSON shims borrow a
+// Builder or Reader from here instead of allocating one per call.
+package jsonbuild
+
+import (
+	"sync"
+
+	json "github.com/rsms/go-json"
+)
+
+var builderPool = sync.Pool{New: func() interface{} { return new(json.Builder) }}
+var readerPool = sync.Pool{New: func() interface{} { return new(json.Reader) }}
+
+// GetBuilder returns a reset Builder from the pool. Callers must return it
+// with PutBuilder once they are done with its output.
+func GetBuilder() *json.Builder {
+	b := builderPool.Get().(*json.Builder)
+	b.Reset()
+	return b
+}
+
+// PutBuilder returns a Builder acquired via GetBuilder to the pool.
+func PutBuilder(b *json.Builder) {
+	builderPool.Put(b)
+}
+
+// GetReader returns a Reader positioned at the start of data, borrowed from
+// the pool. Callers must return it with PutReader once done.
+func GetReader(data []byte) *json.Reader {
+	r := readerPool.Get().(*json.Reader)
+	r.ResetBytes(data)
+	return r
+}
+
+// PutReader returns a Reader acquired via GetReader to the pool.
+func PutReader(r *json.Reader) {
+	readerPool.Put(r)
+}
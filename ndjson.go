@@ -0,0 +1,64 @@
+package json
+
+import (
+	"fmt"
+	"io"
+)
+
+// LineBuilder builds newline-delimited JSON (NDJSON): a stream of complete
+// JSON values, one per line. It's a Builder with one addition: Next, which
+// finalizes the value built so far as a line by writing a trailing '\n'.
+type LineBuilder struct {
+	Builder
+}
+
+// NewLineBuilder returns a LineBuilder that flushes each finalized line to w.
+func NewLineBuilder(w io.Writer) *LineBuilder {
+	lb := &LineBuilder{}
+	lb.SetOutput(w)
+	return lb
+}
+
+// Next finalizes the value built so far as one NDJSON line, writing a
+// trailing '\n', and prepares the LineBuilder to build the next line. The
+// caller must have closed every StartObject/StartArray it opened first.
+func (l *LineBuilder) Next() error {
+	if l.nestdepth != 0 {
+		l.setError(fmt.Errorf("ndjson: cannot finalize line: unterminated object or array"))
+		return l.Err
+	}
+	l.Write([]byte{'\n'})
+	l.flush()
+	l.state = builderInit
+	return l.Err
+}
+
+// LineReader reads newline-delimited JSON (NDJSON): a stream of complete
+// JSON values, one per line. It's a Reader with one addition: Next, which
+// advances to the next line.
+type LineReader struct {
+	Reader
+}
+
+// NewLineReader returns a LineReader positioned at the first line of data.
+func NewLineReader(data []byte) *LineReader {
+	lr := &LineReader{}
+	lr.ResetBytes(data)
+	return lr
+}
+
+// Next advances to the next line, so its value can be read with the usual
+// Reader methods (ObjectStart, Key, Int, ...). It returns false once there
+// are no more lines; use Err to tell a clean EOF apart from a real error.
+// The caller must fully read (or Discard) the current line before calling
+// Next again.
+func (l *LineReader) Next() bool {
+	_, err := l.peek()
+	if err != nil {
+		if err != io.EOF {
+			l.setError(err)
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,73 @@
+package json
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestLineBuilder(t *testing.T) {
+	var buf bytes.Buffer
+	lb := NewLineBuilder(&buf)
+
+	lb.StartObject()
+	lb.IntProp("n", 1, 32)
+	lb.EndObject()
+	if err := lb.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	lb.StartArray()
+	lb.Str("a")
+	lb.Str("b")
+	lb.EndArray()
+	if err := lb.Next(); err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+
+	want := "{\"n\":1}\n[\"a\",\"b\"]\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+}
+
+func TestLineBuilderUnterminated(t *testing.T) {
+	var lb LineBuilder
+	lb.StartObject()
+	lb.IntProp("n", 1, 64)
+	if err := lb.Next(); err == nil {
+		t.Fatal("expected an error finalizing a line with an open object")
+	}
+}
+
+func TestLineReader(t *testing.T) {
+	lr := NewLineReader([]byte("{\"n\":1}\n[\"a\",\"b\"]\n"))
+
+	if !lr.Next() {
+		t.Fatalf("Next: %v", lr.Err())
+	}
+	lr.ObjectStart()
+	for lr.More() {
+		if lr.Key() != "n" || lr.Int(64) != 1 {
+			t.Fatal("expected n:1 on the first line")
+		}
+	}
+
+	if !lr.Next() {
+		t.Fatalf("Next: %v", lr.Err())
+	}
+	lr.ArrayStart()
+	var got []string
+	for lr.More() {
+		got = append(got, lr.Str())
+	}
+	if len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Fatalf("got %v, want [a b]", got)
+	}
+
+	if lr.Next() {
+		t.Fatal("expected no third line")
+	}
+	if err := lr.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
@@ -1,37 +1,52 @@
 package json
 
 import (
-	"bytes"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
 )
 
+// Reader is a low-level, caller-driven JSON reader: the mirror image of
+// Builder. It used to wrap encoding/json.Decoder, which boxes every parsed
+// value (a string, a float64, a delimiter) into a json.Token interface on
+// every call; Reader is now a thin layer over an internal byte-level
+// scanner, so walking a document only allocates when a caller asks for an
+// owned value (Str, Key) or when a string needs unescaping.
 type Reader struct {
-	d          *json.Decoder
-	err        error
-	tok        json.Token // most recently parsed token
-	delimstack []json.Delim
-	delim      json.Delim // top of logical delimstack
+	s       scanner
+	err     error
+	scratch []byte      // unescape scratch buffer, reused across calls
+	stack   []TokenKind // closing token kind (ObjectEnd/ArrayEnd) expected for each open container
+
+	peeked  bool
+	peekTok token
+	peekErr error
 }
 
 func NewReader(data []byte) *Reader {
-	return &Reader{
-		d: json.NewDecoder(bytes.NewReader(data)),
-	}
+	r := &Reader{}
+	r.s.reset(data)
+	return r
 }
 
+// Reset discards any buffered state and prepares c to read from r.
 func (c *Reader) Reset(r io.Reader) {
-	c.d = json.NewDecoder(r)
-	if c.delimstack != nil {
-		c.delimstack = c.delimstack[:0]
-	}
+	c.s.resetReader(r)
+	c.resetState()
 }
 
 func (c *Reader) ResetBytes(data []byte) {
-	c.Reset(bytes.NewReader(data))
+	c.s.reset(data)
+	c.resetState()
+}
+
+func (c *Reader) resetState() {
+	if c.stack != nil {
+		c.stack = c.stack[:0]
+	}
+	c.peeked = false
+	c.err = nil
 }
 
 func (c *Reader) Err() error { return c.err }
@@ -39,9 +54,8 @@ func (c *Reader) Err() error { return c.err }
 func (c *Reader) setError(err error) {
 	if c.err == nil {
 		if numerr, ok := err.(*strconv.NumError); ok {
-			e := numerr.Unwrap()
-			if e == strconv.ErrSyntax {
-				err = c.errExpected("number")
+			if numerr.Unwrap() == strconv.ErrSyntax {
+				err = fmt.Errorf("expected number but got invalid number literal %q", numerr.Num)
 			}
 		}
 		c.err = err
@@ -54,142 +68,230 @@ func (c *Reader) setErrorf(format string, args ...interface{}) {
 	}
 }
 
-func (c *Reader) errExpected(expected string) error {
-	var actual string
-	if d, ok := c.tok.(json.Delim); ok {
-		actual = fmt.Sprint(d)
-	} else {
-		actual = fmt.Sprintf("%T", c.tok)
+// unexpected records a "expected X but got Y" error for a token that didn't
+// match what the caller asked for.
+func (c *Reader) unexpected(expected string, got token) {
+	c.setError(fmt.Errorf("expected %s but got %s at offset %d", expected, got.kind, c.s.off+int64(got.start)))
+}
+
+// peek returns the next token without consuming it, scanning it from the
+// underlying scanner the first time it's asked for.
+func (c *Reader) peek() (token, error) {
+	if !c.peeked {
+		c.peekTok, c.peekErr = c.s.next()
+		c.peeked = true
 	}
-	return fmt.Errorf("expected %s but got %s at offset %d", expected, actual, c.d.InputOffset())
+	return c.peekTok, c.peekErr
 }
 
-func (c *Reader) setErrorExpected(expected string) {
-	c.setError(c.errExpected(expected))
+// advance returns and consumes the next token.
+func (c *Reader) advance() (token, error) {
+	t, err := c.peek()
+	c.peeked = false
+	return t, err
 }
 
-func (c *Reader) next() json.Token {
-	t, err := c.d.Token()
-	c.tok = t
-	if err != nil {
-		// if err == io.EOF
-		c.setError(err)
-	}
-	return t
+// atContainerEnd reports whether t is the closing token of the innermost
+// open container, i.e. there is nothing more to read in it.
+func (c *Reader) atContainerEnd(t token) bool {
+	return len(c.stack) > 0 && t.kind == c.stack[len(c.stack)-1]
 }
 
 func (c *Reader) Key() string {
-	if c.d.More() {
-		t := c.next()
-		if s, ok := t.(string); ok {
-			return s
-		}
-		c.setErrorExpected("key")
+	t, err := c.peek()
+	if err != nil {
+		c.setError(err)
+		return ""
 	}
-	return ""
+	if c.atContainerEnd(t) {
+		return ""
+	}
+	c.advance()
+	if t.kind != TokenString {
+		c.unexpected("key", t)
+		return ""
+	}
+	b, err := c.s.strBytes(t, &c.scratch)
+	if err != nil {
+		c.setError(err)
+		return ""
+	}
+	return string(b)
 }
 
-func (c *Reader) pushDelim(d json.Delim) bool {
-	tok := c.next()
-	ok := tok == d
-	if !ok {
-		c.setErrorExpected(fmt.Sprint(d))
+func (c *Reader) pushContainer(open, close TokenKind, what string) bool {
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
 		return false
 	}
-	c.delimstack = append(c.delimstack, c.delim)
-	c.delim = d
-	return true
-}
-
-func (c *Reader) popDelim() {
-	t := c.next()
-	if d, ok := t.(json.Delim); ok {
-		expect := json.Delim(rune(d) - 2) // i.e. '['+2 = ']', '{'+2 = '}'
-		if expect != c.delim {
-			// delimiter mismatch, e.g. "[1,2,}"
-			c.errExpected(fmt.Sprint(expect))
-		}
-		c.delim = c.delimstack[len(c.delimstack)-1]
-		c.delimstack = c.delimstack[:len(c.delimstack)-1]
+	if t.kind != open {
+		c.unexpected(what, t)
+		return false
 	}
+	c.stack = append(c.stack, close)
+	return true
 }
 
 func (c *Reader) ObjectStart() bool {
-	return c.pushDelim(json.Delim('{'))
+	return c.pushContainer(TokenObjectStart, TokenObjectEnd, "'{'")
 }
 
 func (c *Reader) ArrayStart() bool {
-	return c.pushDelim(json.Delim('['))
+	return c.pushContainer(TokenArrayStart, TokenArrayEnd, "'['")
 }
 
+// More reports whether there is another element (key or value) to read in
+// the current object/array, consuming its closing token if not.
 func (c *Reader) More() bool {
-	if c.d.More() {
-		return true
+	t, err := c.peek()
+	if err != nil {
+		c.setError(err)
+		return false
 	}
-	// consume ending delimiter
-	c.popDelim()
-	return false
+	if c.atContainerEnd(t) {
+		c.advance()
+		c.stack = c.stack[:len(c.stack)-1]
+		return false
+	}
+	return true
 }
 
 func (c *Reader) Int(bitsize int) int64 {
-	t := c.next()
-	switch v := t.(type) {
-	case float64:
-		return int64(v)
-	case string:
-		i, err := strconv.ParseInt(v, 10, bitsize)
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return 0
+	}
+	switch t.kind {
+	case TokenNumber:
+		i, err := strconv.ParseInt(string(c.s.raw(t)), 10, bitsize)
+		if err != nil {
+			c.setError(err)
+		}
+		return i
+	case TokenString:
+		b, err := c.s.strBytes(t, &c.scratch)
+		if err != nil {
+			c.setError(err)
+			return 0
+		}
+		i, err := strconv.ParseInt(string(b), 10, bitsize)
 		if err != nil {
 			c.setError(err)
 		}
 		return i
 	default:
-		c.setErrorExpected("number")
+		c.unexpected("number", t)
 	}
 	return 0
 }
 
 func (c *Reader) Uint(bitsize int) uint64 {
-	t := c.next()
-	switch v := t.(type) {
-	case float64:
-		return uint64(v)
-	case string:
-		i, err := strconv.ParseUint(v, 10, bitsize)
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return 0
+	}
+	switch t.kind {
+	case TokenNumber:
+		i, err := strconv.ParseUint(string(c.s.raw(t)), 10, bitsize)
+		if err != nil {
+			c.setError(err)
+		}
+		return i
+	case TokenString:
+		b, err := c.s.strBytes(t, &c.scratch)
+		if err != nil {
+			c.setError(err)
+			return 0
+		}
+		i, err := strconv.ParseUint(string(b), 10, bitsize)
 		if err != nil {
 			c.setError(err)
 		}
 		return i
 	default:
-		c.setErrorExpected("number")
+		c.unexpected("number", t)
 	}
 	return 0
 }
 
 func (c *Reader) Float(bitsize int) float64 {
-	t := c.next()
-	if v, ok := t.(float64); ok {
-		return v
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return 0
+	}
+	if t.kind != TokenNumber {
+		c.unexpected("number", t)
+		return 0
 	}
-	c.setErrorExpected("number")
-	return 0.0
+	f, err := strconv.ParseFloat(string(c.s.raw(t)), bitsize)
+	if err != nil {
+		c.setError(err)
+	}
+	return f
 }
 
 func (c *Reader) Bool() bool {
-	t := c.next()
-	if v, ok := t.(bool); ok {
-		return v
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return false
+	}
+	switch t.kind {
+	case TokenTrue:
+		return true
+	case TokenFalse:
+		return false
+	default:
+		c.unexpected("boolean", t)
+		return false
 	}
-	c.setErrorExpected("boolean")
-	return false
 }
 
 func (c *Reader) Str() string {
-	t := c.next()
-	if s, ok := t.(string); ok {
-		return s
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return ""
+	}
+	if t.kind != TokenString {
+		c.unexpected("string", t)
+		return ""
 	}
-	c.setErrorExpected("string")
-	return ""
+	b, err := c.s.strBytes(t, &c.scratch)
+	if err != nil {
+		c.setError(err)
+		return ""
+	}
+	return string(b)
+}
+
+// StrBytes returns the string value of the current token as a []byte. When
+// the string contains no escape sequences, it's a direct subslice of the
+// input buffer: for a caller-owned []byte (NewReader/ResetBytes) that is the
+// caller's own slice and costs no allocation, and for an io.Reader input
+// (Reset) it is the Reader's internal buffer. A string with escape sequences
+// is unescaped into a reusable scratch buffer. Either way, the returned slice is only valid until the next call
+// to the Reader — copy it if it needs to outlive that.
+func (c *Reader) StrBytes() []byte {
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return nil
+	}
+	if t.kind != TokenString {
+		c.unexpected("string", t)
+		return nil
+	}
+	b, err := c.s.strBytes(t, &c.scratch)
+	if err != nil {
+		c.setError(err)
+		return nil
+	}
+	return b
 }
 
 func (c *Reader) Blob() []byte {
@@ -204,10 +306,105 @@ func (c *Reader) Blob() []byte {
 	return buf
 }
 
-// Discard the next value
+// Discard the next value, recursing into objects and arrays as needed. A
+// scalar (string, number, bool, null) is discarded by simply consuming it.
 func (c *Reader) Discard() {
-	switch t := c.next().(type) {
-		case json.Delim: // one of [ ] { }
-			c.setError(fmt.Errorf("UNIMPLEMENTED json.Reader.Discard object (%q)", t))
+	t, err := c.advance()
+	if err != nil {
+		c.setError(err)
+		return
+	}
+	c.discardContainer(t.kind)
+}
+
+// discardContainer, given the kind of a token already consumed by the
+// caller, skips the rest of its value if that token opened an object or
+// array. It tracks nesting with a stack of expected closing token kinds
+// rather than recursion, so discarding a deeply nested value doesn't grow
+// the Go call stack, while still catching mismatched brackets (e.g. an
+// array opened with '[' and closed with '}') the same way the normal
+// walking API does.
+func (c *Reader) discardContainer(kind TokenKind) {
+	var want TokenKind
+	switch kind {
+	case TokenObjectStart:
+		want = TokenObjectEnd
+	case TokenArrayStart:
+		want = TokenArrayEnd
+	default:
+		return
+	}
+	stack := []TokenKind{want}
+	for len(stack) > 0 {
+		t, err := c.advance()
+		if err != nil {
+			c.setError(err)
+			return
+		}
+		switch t.kind {
+		case TokenObjectStart:
+			stack = append(stack, TokenObjectEnd)
+		case TokenArrayStart:
+			stack = append(stack, TokenArrayEnd)
+		case TokenObjectEnd, TokenArrayEnd:
+			if t.kind != stack[len(stack)-1] {
+				c.unexpected(stack[len(stack)-1].String(), t)
+				return
+			}
+			stack = stack[:len(stack)-1]
+		}
+	}
+}
+
+// SkipKey discards the value belonging to the key most recently returned
+// by Key. It's a convenience for the default case of a switch over known
+// keys while iterating an object, e.g.:
+//
+//	for r.More() {
+//	    switch r.Key() {
+//	    case "name":
+//	        name = r.Str()
+//	    default:
+//	        r.SkipKey()
+//	    }
+//	}
+func (c *Reader) SkipKey() {
+	c.Discard()
+}
+
+// DiscardRest discards every remaining key/value or element of the
+// innermost open object or array, leaving the Reader positioned right
+// after its closing token — as if More() had been called until it
+// returned false. It's useful to bail out of a container early once a
+// caller has read everything it needs from it.
+func (c *Reader) DiscardRest() {
+	if len(c.stack) == 0 {
+		c.setErrorf("DiscardRest called with no open object or array")
+		return
+	}
+	want := c.stack[len(c.stack)-1]
+	for {
+		t, err := c.peek()
+		if err != nil {
+			c.setError(err)
+			return
+		}
+		if t.kind == want {
+			c.advance()
+			c.stack = c.stack[:len(c.stack)-1]
+			return
+		}
+		c.advance()
+		if want == TokenObjectEnd {
+			// t was a key; now discard its value.
+			vt, err := c.advance()
+			if err != nil {
+				c.setError(err)
+				return
+			}
+			c.discardContainer(vt.kind)
+		} else {
+			c.discardContainer(t.kind)
+		}
 	}
 }
@@ -0,0 +1,210 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildBenchFixture builds a synthetic document shaped like the classic
+// encoding/json "code.json" benchmark fixture (a big array of nested
+// objects mixing strings, numbers and sub-arrays), sized to land around
+// 1.8MB. One node carries an oversized Blob field, longer than
+// scanner.minRead, so that scanning it from an io.Reader (BenchmarkReader
+// uses a plain []byte and never touches this path) can't complete within a
+// single fill() and has to exercise the buffer-doubling growth branch in
+// scanner.fill at least once.
+func buildBenchFixture() []byte {
+	type node struct {
+		Name     string   `json:"name"`
+		ID       int      `json:"id"`
+		Tags     []string `json:"tags"`
+		Score    float64  `json:"score"`
+		Active   bool     `json:"active"`
+		Children []int    `json:"children"`
+		Blob     string   `json:"blob,omitempty"`
+	}
+	var nodes []node
+	for i := 0; i < 10700; i++ {
+		n := node{
+			Name:     fmt.Sprintf("node-%d-the quick brown fox jumps over the lazy dog", i),
+			ID:       i,
+			Tags:     []string{"alpha", "beta", "gamma"},
+			Score:    float64(i) * 1.5,
+			Active:   i%2 == 0,
+			Children: []int{i, i + 1, i + 2, i + 3},
+		}
+		if i == 0 {
+			n.Blob = strings.Repeat("x", minRead*2)
+		}
+		nodes = append(nodes, n)
+	}
+	data, err := json.Marshal(nodes)
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
+
+var benchFixture = buildBenchFixture()
+
+// readWithReader walks data with r, touching every field, mirroring what
+// readWithEncodingJSONDecoder does with encoding/json.Decoder's Token() API
+// so the two are doing comparable work.
+func readWithReader(b *testing.B, r *Reader) {
+	r.ArrayStart()
+	for r.More() {
+		r.ObjectStart()
+		for r.More() {
+			switch r.Key() {
+			case "name":
+				r.Str()
+			case "id":
+				r.Int(64)
+			case "tags":
+				r.ArrayStart()
+				for r.More() {
+					r.Str()
+				}
+			case "score":
+				r.Float(64)
+			case "active":
+				r.Bool()
+			case "children":
+				r.ArrayStart()
+				for r.More() {
+					r.Int(64)
+				}
+			default:
+				r.Discard()
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		b.Fatalf("Reader error: %v", err)
+	}
+}
+
+// readWithEncodingJSONUnmarshal decodes data in one shot via
+// encoding/json.Unmarshal into a fully typed slice. This is the idiomatic
+// way most callers use encoding/json, but it takes a different (reflection
+// and struct-tag-cache driven) code path than the token-at-a-time walk
+// readWithReader performs, so its numbers aren't directly comparable to
+// BenchmarkReader; see readWithEncodingJSONDecoder for that comparison.
+func readWithEncodingJSONUnmarshal(b *testing.B, data []byte) {
+	type node struct {
+		Name     string   `json:"name"`
+		ID       int      `json:"id"`
+		Tags     []string `json:"tags"`
+		Score    float64  `json:"score"`
+		Active   bool     `json:"active"`
+		Children []int    `json:"children"`
+	}
+	var nodes []node
+	if err := json.Unmarshal(data, &nodes); err != nil {
+		b.Fatalf("encoding/json error: %v", err)
+	}
+}
+
+// readWithEncodingJSONDecoder walks data with encoding/json.Decoder's
+// Token() API, touching the same fields readWithReader does, so the two
+// benchmarks measure comparable token-at-a-time work.
+func readWithEncodingJSONDecoder(b *testing.B, data []byte) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	token := func() json.Token {
+		t, err := dec.Token()
+		if err != nil {
+			b.Fatalf("encoding/json.Decoder error: %v", err)
+		}
+		return t
+	}
+	// skipJSONValue discards a value whose opening token (a scalar, or a
+	// json.Delim for '[' or '{') has already been read via token().
+	var skipJSONValue func(t json.Token)
+	skipJSONValue = func(t json.Token) {
+		d, ok := t.(json.Delim)
+		if !ok {
+			return
+		}
+		for dec.More() {
+			if d == '{' {
+				token() // key
+			}
+			skipJSONValue(token())
+		}
+		token() // closing delim
+	}
+
+	token() // [
+	for dec.More() {
+		token() // {
+		for dec.More() {
+			key := token().(string)
+			switch key {
+			case "name", "id", "score", "active":
+				token()
+			case "tags":
+				token() // [
+				for dec.More() {
+					token()
+				}
+				token() // ]
+			case "children":
+				token() // [
+				for dec.More() {
+					token()
+				}
+				token() // ]
+			default:
+				skipJSONValue(token())
+			}
+		}
+		token() // }
+	}
+	token() // ]
+}
+
+func BenchmarkReader(b *testing.B) {
+	b.SetBytes(int64(len(benchFixture)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		readWithReader(b, NewReader(benchFixture))
+	}
+}
+
+// BenchmarkReaderFromReader walks benchFixture through Reader.Reset(io.Reader)
+// instead of NewReader([]byte), so (unlike BenchmarkReader, which hands the
+// scanner a caller-owned slice it never grows or compacts) it actually
+// exercises scanner.fill's ring buffer, including its buffer-doubling
+// growth branch, at the ~1.8MB scale buildBenchFixture is sized for.
+func BenchmarkReaderFromReader(b *testing.B) {
+	b.SetBytes(int64(len(benchFixture)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	r := &Reader{}
+	for i := 0; i < b.N; i++ {
+		r.Reset(bytes.NewReader(benchFixture))
+		readWithReader(b, r)
+	}
+}
+
+func BenchmarkEncodingJSONUnmarshal(b *testing.B) {
+	b.SetBytes(int64(len(benchFixture)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		readWithEncodingJSONUnmarshal(b, benchFixture)
+	}
+}
+
+func BenchmarkEncodingJSONDecoder(b *testing.B) {
+	b.SetBytes(int64(len(benchFixture)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		readWithEncodingJSONDecoder(b, benchFixture)
+	}
+}
@@ -0,0 +1,318 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"testing"
+)
+
+func TestReaderScalars(t *testing.T) {
+	data := []byte(`{"a":1,"b":[1,2,3],"c":"hello\nworld","d":true,"e":false,"f":-12.5e2,"g":"unicode åäö 😀"}`)
+	r := NewReader(data)
+	r.ObjectStart()
+	seen := map[string]bool{}
+	for r.More() {
+		switch r.Key() {
+		case "a":
+			seen["a"] = true
+			if v := r.Int(64); v != 1 {
+				t.Errorf("a = %d, want 1", v)
+			}
+		case "b":
+			seen["b"] = true
+			r.ArrayStart()
+			sum := int64(0)
+			for r.More() {
+				sum += r.Int(64)
+			}
+			if sum != 6 {
+				t.Errorf("sum(b) = %d, want 6", sum)
+			}
+		case "c":
+			seen["c"] = true
+			if v := r.Str(); v != "hello\nworld" {
+				t.Errorf("c = %q", v)
+			}
+		case "d":
+			seen["d"] = true
+			if v := r.Bool(); !v {
+				t.Errorf("d = %v, want true", v)
+			}
+		case "e":
+			seen["e"] = true
+			if v := r.Bool(); v {
+				t.Errorf("e = %v, want false", v)
+			}
+		case "f":
+			seen["f"] = true
+			if v := r.Float(64); v != -1250 {
+				t.Errorf("f = %v, want -1250", v)
+			}
+		case "g":
+			seen["g"] = true
+			if v := r.Str(); v != "unicode åäö 😀" {
+				t.Errorf("g = %q", v)
+			}
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, k := range []string{"a", "b", "c", "d", "e", "f", "g"} {
+		if !seen[k] {
+			t.Errorf("key %q was not visited", k)
+		}
+	}
+}
+
+func TestReaderStrBytes(t *testing.T) {
+	data := []byte(`"hello"`)
+	r := NewReader(data)
+	got := r.StrBytes()
+	if string(got) != "hello" {
+		t.Fatalf("StrBytes() = %q", got)
+	}
+	// Zero-copy: StrBytes must alias the caller's buffer, not a copy, when
+	// there's nothing to unescape.
+	if &got[0] != &data[1] {
+		t.Errorf("StrBytes() did not alias the input buffer")
+	}
+}
+
+func TestReaderNestedContainers(t *testing.T) {
+	data := []byte(`{"a":{"b":[1,[2,3],{"c":4}]}}`)
+	r := NewReader(data)
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "a" {
+		t.Fatal("expected key a")
+	}
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "b" {
+		t.Fatal("expected key b")
+	}
+	r.ArrayStart()
+	if !r.More() || r.Int(64) != 1 {
+		t.Fatal("expected 1")
+	}
+	r.ArrayStart()
+	if !r.More() || r.Int(64) != 2 {
+		t.Fatal("expected 2")
+	}
+	if !r.More() || r.Int(64) != 3 {
+		t.Fatal("expected 3")
+	}
+	if r.More() {
+		t.Fatal("expected inner array to be exhausted")
+	}
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "c" || r.Int(64) != 4 {
+		t.Fatal("expected c:4")
+	}
+	if r.More() {
+		t.Fatal("expected object to be exhausted")
+	}
+	if r.More() {
+		t.Fatal("expected outer array to be exhausted")
+	}
+	if r.More() {
+		t.Fatal("expected b object to be exhausted")
+	}
+	if r.More() {
+		t.Fatal("expected a object to be exhausted")
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestReaderStreaming(t *testing.T) {
+	// Force the scanner to refill its buffer mid-token by reading from an
+	// io.Reader one byte at a time.
+	data := []byte(`[1,2,3,"a long string that spans several single-byte reads",4]`)
+	r := &Reader{}
+	r.Reset(&byteAtATimeReader{data: data})
+	r.ArrayStart()
+	var got []string
+	for r.More() {
+		switch b := r.peekKindForTest(); b {
+		case TokenNumber:
+			got = append(got, fmt.Sprint(r.Int(64)))
+		default:
+			got = append(got, r.Str())
+		}
+	}
+	want := []string{"1", "2", "3", "a long string that spans several single-byte reads", "4"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// peekKindForTest exposes the kind of the next token, for tests that need
+// to pick an accessor method without already knowing the document shape.
+func (c *Reader) peekKindForTest() TokenKind {
+	t, err := c.peek()
+	if err != nil {
+		return TokenEOF
+	}
+	return t.kind
+}
+
+type byteAtATimeReader struct {
+	data []byte
+	pos  int
+}
+
+func (r *byteAtATimeReader) Read(p []byte) (int, error) {
+	if r.pos >= len(r.data) {
+		return 0, io.EOF
+	}
+	p[0] = r.data[r.pos]
+	r.pos++
+	return 1, nil
+}
+
+func TestReaderDiscardScalar(t *testing.T) {
+	r := NewReader([]byte(`{"a":"skip me","b":2}`))
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "a" {
+		t.Fatal("expected key a")
+	}
+	r.Discard()
+	if r.Err() != nil {
+		t.Fatalf("unexpected error discarding scalar: %v", r.Err())
+	}
+	r.More()
+	if r.Key() != "b" || r.Int(64) != 2 {
+		t.Fatal("expected b:2 after discarding a")
+	}
+}
+
+func TestReaderDiscardContainer(t *testing.T) {
+	r := NewReader([]byte(`{"a":{"x":[1,2,{"y":3}],"z":"skip"},"b":2}`))
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "a" {
+		t.Fatal("expected key a")
+	}
+	r.Discard()
+	if r.Err() != nil {
+		t.Fatalf("unexpected error discarding object: %v", r.Err())
+	}
+	r.More()
+	if r.Key() != "b" || r.Int(64) != 2 {
+		t.Fatal("expected b:2 after discarding a")
+	}
+}
+
+func TestReaderDiscardMismatchedBrackets(t *testing.T) {
+	r := NewReader([]byte(`{"a":[1,2},"b":3}`))
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "a" {
+		t.Fatal("expected key a")
+	}
+	r.Discard()
+	if r.Err() == nil {
+		t.Fatal("expected an error discarding an array closed with '}'")
+	}
+}
+
+func TestReaderSkipKey(t *testing.T) {
+	r := NewReader([]byte(`{"known":1,"unknown":{"nested":[1,2,3]},"other":2}`))
+	r.ObjectStart()
+	var known, other int64
+	for r.More() {
+		switch r.Key() {
+		case "known":
+			known = r.Int(64)
+		case "other":
+			other = r.Int(64)
+		default:
+			r.SkipKey()
+		}
+	}
+	if err := r.Err(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if known != 1 || other != 2 {
+		t.Fatalf("known=%d, other=%d", known, other)
+	}
+}
+
+func TestReaderDiscardRest(t *testing.T) {
+	r := NewReader([]byte(`{"a":[1,2,3,4,5],"b":2}`))
+	r.ObjectStart()
+	r.More()
+	if r.Key() != "a" {
+		t.Fatal("expected key a")
+	}
+	r.ArrayStart()
+	if !r.More() || r.Int(64) != 1 {
+		t.Fatal("expected first array element to be 1")
+	}
+	r.DiscardRest()
+	if r.Err() != nil {
+		t.Fatalf("unexpected error discarding rest of array: %v", r.Err())
+	}
+	r.More()
+	if r.Key() != "b" || r.Int(64) != 2 {
+		t.Fatal("expected b:2 after discarding rest of a")
+	}
+}
+
+func TestReaderMalformed(t *testing.T) {
+	r := NewReader([]byte(`{"a":tru}`))
+	r.ObjectStart()
+	r.More()
+	r.Key()
+	r.Bool()
+	if r.Err() == nil {
+		t.Fatal("expected an error for truncated literal")
+	}
+}
+
+func TestReaderQuotedNumber(t *testing.T) {
+	r := NewReader([]byte(`"123"`))
+	if v := r.Int(64); v != 123 {
+		t.Fatalf("Int() = %d, want 123 for a quoted number", v)
+	}
+}
+
+func TestReaderResetBytesReusesState(t *testing.T) {
+	r := NewReader([]byte(`{"a":1}`))
+	r.ObjectStart()
+	r.More()
+	r.Key()
+	r.Int(64)
+	r.More() // consumes the closing '}'
+
+	r.ResetBytes([]byte(`[1,2]`))
+	r.ArrayStart()
+	sum := int64(0)
+	for r.More() {
+		sum += r.Int(64)
+	}
+	if sum != 3 || r.Err() != nil {
+		t.Fatalf("sum = %d, err = %v", sum, r.Err())
+	}
+}
+
+func TestReaderUnreadableUTF8(t *testing.T) {
+	r := NewReader([]byte("\"\xff\xfe\""))
+	r.Str()
+	if r.Err() == nil {
+		t.Fatal("expected an error for invalid UTF-8 in a string")
+	}
+}
@@ -0,0 +1,432 @@
+package json
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// TokenKind identifies the shape of a token produced by the scanner.
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenObjectStart
+	TokenObjectEnd
+	TokenArrayStart
+	TokenArrayEnd
+	TokenString
+	TokenNumber
+	TokenTrue
+	TokenFalse
+	TokenNull
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenEOF:
+		return "EOF"
+	case TokenObjectStart:
+		return "'{'"
+	case TokenObjectEnd:
+		return "'}'"
+	case TokenArrayStart:
+		return "'['"
+	case TokenArrayEnd:
+		return "']'"
+	case TokenString:
+		return "string"
+	case TokenNumber:
+		return "number"
+	case TokenTrue, TokenFalse:
+		return "boolean"
+	case TokenNull:
+		return "null"
+	}
+	return "unknown"
+}
+
+// token is a lightweight, non-owning reference into scanner.buf. Unlike
+// encoding/json.Token it never boxes a value in an interface{} or allocates;
+// the payload (string contents, number digits) is only materialized from
+// buf[start:end] when the caller asks for it.
+type token struct {
+	kind    TokenKind
+	start   int
+	end     int  // exclusive; for TokenString this excludes the surrounding quotes
+	escaped bool // TokenString only: contents contain a backslash escape
+}
+
+// scanner is a byte-level, allocation-free JSON tokenizer. It can operate
+// directly on a caller-owned []byte (the common case, used by NewReader),
+// or pull more input from an io.Reader into an internal buffer on demand.
+//
+// scanner does not itself track nested object/array structure or insert
+// the comma/colon bookkeeping that the old encoding/json.Decoder-based
+// Reader relied on: commas and colons are structural noise that scanner
+// silently skips between tokens, so callers just see the sequence of
+// meaningful tokens that make up the document.
+type scanner struct {
+	buf        []byte // current window of input
+	pos        int    // next unread byte in buf
+	end        int    // end of valid data in buf
+	tokenStart int    // pos at which the token currently being scanned began; fill() keeps every byte from here on
+
+	r        io.Reader // non-nil when reading from a stream
+	rErr     error     // sticky error from the last Read, including io.EOF
+	off      int64     // total bytes consumed from buf before the current window (for error offsets)
+	fromUser bool      // true if buf was handed to us by the caller (NewReader/ResetBytes): never grown or compacted
+}
+
+// minRead is how much free space scanner.fill tries to make available
+// before issuing a Read, to amortize small reads from slow io.Readers.
+const minRead = 4096
+
+func (s *scanner) reset(data []byte) {
+	s.buf = data
+	s.pos = 0
+	s.end = len(data)
+	s.r = nil
+	s.rErr = nil
+	s.off = 0
+	s.fromUser = true
+}
+
+func (s *scanner) resetReader(r io.Reader) {
+	if cap(s.buf) < minRead || s.fromUser {
+		s.buf = make([]byte, minRead)
+	}
+	s.buf = s.buf[:0]
+	s.pos = 0
+	s.end = 0
+	s.tokenStart = 0
+	s.r = r
+	s.rErr = nil
+	s.off = 0
+	s.fromUser = false
+}
+
+// inputOffset returns the absolute byte offset of s.pos, for error messages.
+func (s *scanner) inputOffset() int64 {
+	return s.off + int64(s.pos)
+}
+
+// fill slides any bytes at or after tokenStart to the front of buf (acting
+// as a sliding window rather than a true circular buffer, which is simpler
+// and has the same effect: bounded memory regardless of stream length) and
+// reads more data from s.r. It only discards bytes before tokenStart, so
+// a token's raw bytes stay contiguous and valid even if scanning it (e.g.
+// a long string) takes more than one fill. It reports whether at least one
+// more byte became available.
+func (s *scanner) fill() bool {
+	if s.r == nil || s.rErr != nil {
+		return false
+	}
+	if s.tokenStart > 0 {
+		n := copy(s.buf, s.buf[s.tokenStart:s.end])
+		s.off += int64(s.tokenStart)
+		s.end = n
+		s.pos -= s.tokenStart
+		s.tokenStart = 0
+	}
+	if s.end == cap(s.buf) {
+		// Buffer is full of unread bytes; grow it.
+		grown := make([]byte, cap(s.buf)*2)
+		copy(grown, s.buf[:s.end])
+		s.buf = grown[:s.end]
+	}
+	n, err := s.r.Read(s.buf[s.end:cap(s.buf)])
+	s.buf = s.buf[:s.end+n]
+	s.end += n
+	if err != nil {
+		s.rErr = err
+	}
+	return n > 0
+}
+
+// byteAt returns the byte at s.pos, reading more input if needed, and
+// reports whether a byte was available (false at EOF). It always reads at
+// s.pos rather than taking a position argument because fill() can shift
+// s.pos out from under a caller-held snapshot of it.
+func (s *scanner) byteAt() (byte, bool) {
+	for s.pos >= s.end {
+		if !s.fill() {
+			return 0, false
+		}
+	}
+	return s.buf[s.pos], true
+}
+
+// decodeRuneAt decodes the UTF-8 rune starting at s.pos, reading more
+// input if needed so that a multi-byte rune isn't truncated by a buffer
+// boundary. It returns (utf8.RuneError, 0) if s.pos is at EOF, and
+// (utf8.RuneError, 1) if the bytes at s.pos are not valid UTF-8.
+func (s *scanner) decodeRuneAt() (rune, int) {
+	for s.pos+utf8.UTFMax > s.end {
+		if !s.fill() {
+			break
+		}
+	}
+	if s.pos >= s.end {
+		return utf8.RuneError, 0
+	}
+	end := s.end
+	if end > s.pos+utf8.UTFMax {
+		end = s.pos + utf8.UTFMax
+	}
+	return utf8.DecodeRune(s.buf[s.pos:end])
+}
+
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r'
+}
+
+// next scans and returns the next meaningful token, skipping whitespace
+// and the structural ',' and ':' characters.
+func (s *scanner) next() (token, error) {
+	for {
+		s.tokenStart = s.pos
+		b, ok := s.byteAt()
+		if !ok {
+			return token{kind: TokenEOF, start: s.pos, end: s.pos}, s.eofErr()
+		}
+		switch {
+		case isSpace(b):
+			s.pos++
+		case b == ',' || b == ':':
+			s.pos++
+		default:
+			return s.scanValue(b)
+		}
+	}
+}
+
+func (s *scanner) eofErr() error {
+	if s.rErr != nil && s.rErr != io.EOF {
+		return s.rErr
+	}
+	return io.EOF
+}
+
+// scanValue dispatches on the first byte of a value. s.tokenStart has
+// already been set to s.pos by next(); every branch below derives its
+// token's start from s.tokenStart rather than a locally captured position,
+// because a multi-byte scan (scanString, scanNumber, expectLiteral) may
+// call fill() one or more times along the way, and fill() only keeps
+// s.tokenStart (and s.pos, shifted in lockstep) valid across that — a plain
+// local variable captured before the first fill would go stale.
+func (s *scanner) scanValue(b byte) (token, error) {
+	switch {
+	case b == '{':
+		s.pos++
+		return token{kind: TokenObjectStart, start: s.tokenStart, end: s.pos}, nil
+	case b == '}':
+		s.pos++
+		return token{kind: TokenObjectEnd, start: s.tokenStart, end: s.pos}, nil
+	case b == '[':
+		s.pos++
+		return token{kind: TokenArrayStart, start: s.tokenStart, end: s.pos}, nil
+	case b == ']':
+		s.pos++
+		return token{kind: TokenArrayEnd, start: s.tokenStart, end: s.pos}, nil
+	case b == '"':
+		return s.scanString()
+	case b == 't':
+		if err := s.expectLiteral("true"); err != nil {
+			return token{}, err
+		}
+		return token{kind: TokenTrue, start: s.tokenStart, end: s.pos}, nil
+	case b == 'f':
+		if err := s.expectLiteral("false"); err != nil {
+			return token{}, err
+		}
+		return token{kind: TokenFalse, start: s.tokenStart, end: s.pos}, nil
+	case b == 'n':
+		if err := s.expectLiteral("null"); err != nil {
+			return token{}, err
+		}
+		return token{kind: TokenNull, start: s.tokenStart, end: s.pos}, nil
+	case b == '-' || (b >= '0' && b <= '9'):
+		return s.scanNumber()
+	default:
+		return token{}, fmt.Errorf("invalid character %q looking for beginning of value at offset %d", b, s.inputOffset())
+	}
+}
+
+func (s *scanner) expectLiteral(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		b, ok := s.byteAt()
+		if !ok || b != lit[i] {
+			return fmt.Errorf("invalid literal, expected %q at offset %d", lit, s.inputOffset())
+		}
+		s.pos++
+	}
+	return nil
+}
+
+// scanNumber records the raw byte range of a number without parsing it;
+// callers parse lazily (see Reader.Int/Uint/Float) directly from those
+// bytes, so a value that's never read costs nothing beyond this scan.
+func (s *scanner) scanNumber() (token, error) {
+	if b, _ := s.byteAt(); b == '-' {
+		s.pos++
+	}
+	for {
+		b, ok := s.byteAt()
+		if !ok {
+			break
+		}
+		if (b >= '0' && b <= '9') || b == '.' || b == 'e' || b == 'E' || b == '+' || b == '-' {
+			s.pos++
+			continue
+		}
+		break
+	}
+	return token{kind: TokenNumber, start: s.tokenStart, end: s.pos}, nil
+}
+
+// scanString scans a JSON string literal, recording whether it contains any
+// backslash escapes (so callers know whether the raw bytes can be used
+// as-is or need unescaping).
+func (s *scanner) scanString() (token, error) {
+	s.pos++ // opening quote
+	escaped := false
+	for {
+		b, ok := s.byteAt()
+		if !ok {
+			return token{}, fmt.Errorf("unexpected EOF in string literal at offset %d", s.inputOffset())
+		}
+		if b == '"' {
+			end := s.pos
+			s.pos++ // closing quote
+			return token{kind: TokenString, start: s.tokenStart + 1, end: end, escaped: escaped}, nil
+		}
+		if b == '\\' {
+			escaped = true
+			s.pos++
+			if _, ok := s.byteAt(); !ok {
+				return token{}, fmt.Errorf("unexpected EOF in string literal at offset %d", s.inputOffset())
+			}
+			s.pos++
+			continue
+		}
+		if b < 0x20 {
+			return token{}, fmt.Errorf("invalid control character in string literal at offset %d", s.inputOffset())
+		}
+		if b < utf8.RuneSelf {
+			s.pos++
+			continue
+		}
+		r, size := s.decodeRuneAt()
+		if r == utf8.RuneError && size <= 1 {
+			return token{}, fmt.Errorf("invalid UTF-8 in string literal at offset %d", s.inputOffset())
+		}
+		s.pos += size
+	}
+}
+
+// raw returns the bytes of tok. It is only valid to call this before the
+// scanner's buffer has been slid or grown by a subsequent fill (i.e.
+// before the next call that may read more input), unless the scanner is
+// operating on a caller-owned []byte (fromUser), in which case the slice
+// remains valid for the lifetime of that []byte.
+func (s *scanner) raw(tok token) []byte {
+	return s.buf[tok.start:tok.end]
+}
+
+// strBytes returns the unescaped contents of a TokenString token. When the
+// string contains no escapes it returns a direct subslice of the scanner's
+// buffer (zero-copy when fromUser is true); otherwise it decodes escapes
+// into *scratch and returns that.
+func (s *scanner) strBytes(tok token, scratch *[]byte) ([]byte, error) {
+	raw := s.raw(tok)
+	if !tok.escaped {
+		return raw, nil
+	}
+	buf := (*scratch)[:0]
+	for i := 0; i < len(raw); {
+		b := raw[i]
+		if b != '\\' {
+			buf = append(buf, b)
+			i++
+			continue
+		}
+		i++
+		if i >= len(raw) {
+			return nil, fmt.Errorf("truncated escape sequence")
+		}
+		switch raw[i] {
+		case '"':
+			buf = append(buf, '"')
+		case '\\':
+			buf = append(buf, '\\')
+		case '/':
+			buf = append(buf, '/')
+		case 'b':
+			buf = append(buf, '\b')
+		case 'f':
+			buf = append(buf, '\f')
+		case 'n':
+			buf = append(buf, '\n')
+		case 'r':
+			buf = append(buf, '\r')
+		case 't':
+			buf = append(buf, '\t')
+		case 'u':
+			if i+4 >= len(raw) {
+				return nil, fmt.Errorf("truncated unicode escape sequence")
+			}
+			r, err := decodeHex4(raw[i+1 : i+5])
+			if err != nil {
+				return nil, err
+			}
+			i += 4
+			if r >= 0xd800 && r < 0xdc00 && i+6 < len(raw) && raw[i+1] == '\\' && raw[i+2] == 'u' {
+				if r2, err := decodeHex4(raw[i+3 : i+7]); err == nil {
+					if combined := utf16Combine(r, r2); combined != utf8.RuneError {
+						var b4 [utf8.UTFMax]byte
+						n := utf8.EncodeRune(b4[:], combined)
+						buf = append(buf, b4[:n]...)
+						i += 7
+						continue
+					}
+				}
+			}
+			var b4 [utf8.UTFMax]byte
+			n := utf8.EncodeRune(b4[:], rune(r))
+			buf = append(buf, b4[:n]...)
+		default:
+			return nil, fmt.Errorf("invalid escape character %q", raw[i])
+		}
+		i++
+	}
+	*scratch = buf
+	return buf, nil
+}
+
+func decodeHex4(b []byte) (uint16, error) {
+	var v uint16
+	for _, c := range b {
+		v <<= 4
+		switch {
+		case c >= '0' && c <= '9':
+			v |= uint16(c - '0')
+		case c >= 'a' && c <= 'f':
+			v |= uint16(c-'a') + 10
+		case c >= 'A' && c <= 'F':
+			v |= uint16(c-'A') + 10
+		default:
+			return 0, fmt.Errorf("invalid hex digit %q in unicode escape", c)
+		}
+	}
+	return v, nil
+}
+
+// utf16Combine combines a UTF-16 surrogate pair into a single rune, or
+// returns utf8.RuneError if r1/r2 do not form a valid pair.
+func utf16Combine(r1, r2 uint16) rune {
+	if r1 < 0xd800 || r1 >= 0xdc00 || r2 < 0xdc00 || r2 >= 0xe000 {
+		return utf8.RuneError
+	}
+	return ((rune(r1)-0xd800)<<10 | (rune(r2) - 0xdc00)) + 0x10000
+}